@@ -11,7 +11,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 )
 
@@ -38,6 +37,17 @@ type HttpRequestParams struct {
 	customHeaders map[string]string // to set any custom headers, if any
 	contentType   ContentType       // to set content type
 	timeout       time.Duration     // to set custom request timeout if needed, default is 20 secs
+	retryPolicy   *RetryPolicy      // backoff policy used by MakeApiCallWithRetries, defaults to DefaultRetryPolicy()
+
+	oauth2Cache *oauth2TokenCache  // fetches/caches OAuth2 access tokens, see SetOAuth2TokenSource
+	oauth1      *oauth1Credentials // to sign requests per OAuth 1.0a, see SetOAuth1
+
+	multipartFields map[string]string     // form fields to send alongside multipartFiles, see SetMultipartBody
+	multipartFiles  map[string]FileUpload // files to stream as multipart/form-data, see SetMultipartBody
+
+	successMin          int          // lower bound of the success status range, see SetSuccessStatusRange
+	successMax          int          // upper bound of the success status range, see SetSuccessStatusRange
+	expectedStatusCodes map[int]bool // explicit success allow-list, see SetExpectedStatusCodes
 }
 
 func NewHttpRequestParams(endpoint string, method string) *HttpRequestParams {
@@ -107,22 +117,107 @@ func (a *HttpRequestParams) SetTimeout(timeout time.Duration) {
 	a.timeout = timeout
 }
 
-func MakeApiCallWithRetries(ctx context.Context, request *HttpRequestParams, responseAddr interface{}, retriesCount int) (customError error.CustomError) {
-	for i := 0; i <= retriesCount; i++ {
-		customError = MakeApiCall(ctx, request, responseAddr)
-		if customError.Exists() && (customError.ErrorCode() == error.API_REQUEST_ERROR || customError.ErrorCode() == error.API_REQUEST_STATUS_ERROR) {
-			continue
-		} else {
+func (a *HttpRequestParams) SetRetryPolicy(retryPolicy *RetryPolicy) {
+	a.retryPolicy = retryPolicy
+}
+
+// MakeApiCallWithRetries is a thin wrapper around defaultClient.MakeApiCallWithRetries.
+func MakeApiCallWithRetries(ctx context.Context, request *HttpRequestParams, responseAddr interface{}, retriesCount int) (statusErr *HTTPStatusError, customError error.CustomError) {
+	return defaultClient.MakeApiCallWithRetries(ctx, request, responseAddr, retriesCount)
+}
+
+// MakeApiCall is a thin wrapper around defaultClient.MakeApiCall.
+func MakeApiCall(ctx context.Context, request *HttpRequestParams, responseAddr interface{}) (statusErr *HTTPStatusError, customError error.CustomError) {
+	return defaultClient.MakeApiCall(ctx, request, responseAddr)
+}
+
+// MakeApiCallWithRawResponse is a thin wrapper around defaultClient.MakeApiCallWithRawResponse.
+func MakeApiCallWithRawResponse(ctx context.Context, request *HttpRequestParams) (body []byte, statusErr *HTTPStatusError, customError error.CustomError) {
+	return defaultClient.MakeApiCallWithRawResponse(ctx, request)
+}
+
+// MakeApiCallWithRetries behaves like MakeApiCall, retrying up to retriesCount
+// times on transient failures. Between attempts it sleeps according to
+// request.retryPolicy (or DefaultRetryPolicy() if unset), honoring a
+// Retry-After response header when present and aborting early if ctx is
+// done or the policy's MaxElapsedTime is exceeded. Errors that can never
+// succeed on replay (e.g. JSON_SERIALIZATION_ERROR, API_URL_PARSING_ERROR)
+// are returned immediately without retrying.
+func (c *Client) MakeApiCallWithRetries(ctx context.Context, request *HttpRequestParams, responseAddr interface{}, retriesCount int) (statusErr *HTTPStatusError, customError error.CustomError) {
+	policy := request.retryPolicy
+	if policy == nil {
+		policy = DefaultRetryPolicy()
+	}
+
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		var body []byte
+		var statusCode int
+		var headers http.Header
+		body, statusCode, headers, statusErr, customError = c.makeApiCallWithRawResponse(ctx, request)
+
+		if statusErr == nil && !customError.Exists() {
+			if len(body) > 0 {
+				err := json.Unmarshal(body, &responseAddr)
+				if err != nil {
+					customError = error.NewCustomError(error.JSON_DESERIALIZATION_ERROR, err.Error()).
+						WithParam("response", string(body)).
+						WithParam("request", fmt.Sprintf("%+v", request))
+					customError.Log()
+				}
+			}
+			return
+		}
+
+		retryable := false
+		if request.contentType != CONTENT_TYPE_MULTIPART_FORM_DATA {
+			// A multipart body's FileUpload.Reader fields are drained by the
+			// first attempt, so resending here would upload empty file
+			// parts; surface the failure instead of silently corrupting it.
+			if customError.Exists() {
+				retryable = isRetryableError(customError) && (statusCode == 0 || policy.isRetryableStatus(statusCode))
+			} else if statusErr != nil {
+				retryable = policy.isRetryableStatus(statusErr.StatusCode)
+			}
+		}
+
+		if attempt >= retriesCount || !retryable {
+			return
+		}
+
+		delay := policy.nextDelay(attempt)
+		if headers != nil {
+			if retryAfter, ok := retryAfterDelay(headers); ok {
+				delay = retryAfter
+			}
+		}
+
+		if policy.MaxElapsedTime > 0 && time.Since(start)+delay > policy.MaxElapsedTime {
+			return
+		}
+
+		if policy.OnRetry != nil {
+			hookErr := customError
+			if !hookErr.Exists() && statusErr != nil {
+				hookErr = error.NewCustomError(error.API_REQUEST_STATUS_ERROR, fmt.Sprintf("status code: %d", statusErr.StatusCode))
+			}
+			policy.OnRetry(attempt, hookErr, delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			customError = error.NewCustomError(error.API_REQUEST_ERROR, ctx.Err().Error()).
+				WithParam("request", fmt.Sprintf("%+v", request))
 			return
+		case <-time.After(delay):
 		}
 	}
-	return
 }
 
 // responseAddr is the the address of the struct to put the api response
-func MakeApiCall(ctx context.Context, request *HttpRequestParams, responseAddr interface{}) (customError error.CustomError) {
-	body, customError := MakeApiCallWithRawResponse(ctx, request)
-	if customError.Exists() {
+func (c *Client) MakeApiCall(ctx context.Context, request *HttpRequestParams, responseAddr interface{}) (statusErr *HTTPStatusError, customError error.CustomError) {
+	body, _, _, statusErr, customError := c.makeApiCallWithRawResponse(ctx, request)
+	if statusErr != nil || customError.Exists() || len(body) == 0 {
 		return
 	}
 	err := json.Unmarshal(body, &responseAddr)
@@ -132,62 +227,136 @@ func MakeApiCall(ctx context.Context, request *HttpRequestParams, responseAddr i
 			WithParam("request", fmt.Sprintf("%+v", request))
 		customError.Log()
 	}
-	return customError
+	return
 }
 
-func MakeApiCallWithRawResponse(ctx context.Context, request *HttpRequestParams) (body []byte, customError error.CustomError) {
-	var requestBuffer io.Reader
-	if request.body != nil {
-		switch request.contentType {
-		case CONTENT_TYPE_FORM_URL_ENCODED:
-			values, err := form.EncodeToValues(request.body)
-			if err != nil {
-				customError = error.NewCustomError(error.FORM_SERIALIZATION_ERROR, fmt.Sprintf("request: %+v, error: %s", request.body, err.Error()))
-				customError.Log()
-				return
-			}
-			requestBuffer = strings.NewReader(values.Encode())
-		case CONTENT_TYPE_APP_JSON:
-			requestJSONForm, err := json.Marshal(request.body)
-			if err != nil {
-				customError = error.NewCustomError(error.JSON_SERIALIZATION_ERROR, fmt.Sprintf("request: %+v, error: %s", request.body, err.Error()))
-				customError.Log()
-				return
-			}
-			requestBuffer = bytes.NewBuffer(requestJSONForm)
-		}
-	} else {
-		requestBuffer = nil
+func (c *Client) MakeApiCallWithRawResponse(ctx context.Context, request *HttpRequestParams) (body []byte, statusErr *HTTPStatusError, customError error.CustomError) {
+	body, _, _, statusErr, customError = c.makeApiCallWithRawResponse(ctx, request)
+	return
+}
+
+// makeApiCallWithRawResponse performs the request and additionally surfaces
+// the response status code and headers, which MakeApiCallWithRetries needs to
+// make retry decisions but which MakeApiCall/MakeApiCallWithRawResponse don't
+// expose to preserve their existing signatures.
+func (c *Client) makeApiCallWithRawResponse(ctx context.Context, request *HttpRequestParams) (body []byte, statusCode int, headers http.Header, statusErr *HTTPStatusError, customError error.CustomError) {
+	response, customError := c.doRequest(ctx, request)
+	if customError.Exists() {
+		return
+	}
+	body, statusCode, headers, statusErr = readApiResponse(response, request)
+	return
+}
+
+// doRequest encodes request's body, resolves the *http.Client to use, and
+// sends the request, transparently retrying once on a 401 response if
+// request.oauth2Cache is set. The 401 re-send is skipped for
+// CONTENT_TYPE_MULTIPART_FORM_DATA, whose FileUpload.Reader fields are
+// already drained by the first attempt and would upload empty file parts on
+// a resend; the 401 response is returned to the caller as-is instead. The
+// caller owns the returned response's Body and must close it.
+func (c *Client) doRequest(ctx context.Context, request *HttpRequestParams) (response *http.Response, customError error.CustomError) {
+	requestBodyBytes, customError := encodeRequestBody(request)
+	if customError.Exists() {
+		return
 	}
 
-	url, err := url.Parse(request.endpoint)
+	parsedURL, err := url.Parse(request.endpoint)
 	if err != nil {
 		customError = error.NewCustomError(error.API_URL_PARSING_ERROR, fmt.Sprintf("url: %s; error: %s", request.endpoint, err.Error()))
 		customError.Log()
 		return
 	}
 
-	httpRequest, err := http.NewRequest(request.method, url.String(), requestBuffer)
-	if request.queryParams != nil {
-		q := httpRequest.URL.Query()
-		for k, v := range request.queryParams {
-			q.Add(k, v)
+	client := c.httpClient
+	if request.timeout != time.Duration(0) && request.timeout != client.Timeout {
+		// Per-request override: reuse the pooled transport but don't mutate
+		// the shared *http.Client's Timeout out from under other callers.
+		overridden := *client
+		overridden.Timeout = request.timeout
+		client = &overridden
+	}
+
+	// refreshedOAuth2 tracks whether we've already forced a token refresh and
+	// retried once after a 401, per SetOAuth2TokenSource.
+	refreshedOAuth2 := false
+	for {
+		response, customError = c.sendOnce(ctx, request, requestBodyBytes, client, parsedURL, refreshedOAuth2)
+		if customError.Exists() {
+			return
 		}
-		httpRequest.URL.RawQuery = q.Encode()
+
+		if response.StatusCode == http.StatusUnauthorized && request.oauth2Cache != nil && !refreshedOAuth2 &&
+			request.contentType != CONTENT_TYPE_MULTIPART_FORM_DATA {
+			refreshedOAuth2 = true
+			response.Body.Close()
+			continue
+		}
+
+		return
+	}
+}
+
+// encodeRequestBody serializes request.body per request.contentType. It
+// returns a nil slice (not an error) for CONTENT_TYPE_MULTIPART_FORM_DATA,
+// whose streaming body is built fresh per attempt by sendOnce instead.
+func encodeRequestBody(request *HttpRequestParams) (requestBodyBytes []byte, customError error.CustomError) {
+	if request.body == nil {
+		return
+	}
+
+	switch request.contentType {
+	case CONTENT_TYPE_FORM_URL_ENCODED:
+		values, err := form.EncodeToValues(request.body)
+		if err != nil {
+			customError = error.NewCustomError(error.FORM_SERIALIZATION_ERROR, fmt.Sprintf("request: %+v, error: %s", request.body, err.Error()))
+			customError.Log()
+			return
+		}
+		requestBodyBytes = []byte(values.Encode())
+	case CONTENT_TYPE_APP_JSON:
+		requestJSONForm, err := json.Marshal(request.body)
+		if err != nil {
+			customError = error.NewCustomError(error.JSON_SERIALIZATION_ERROR, fmt.Sprintf("request: %+v, error: %s", request.body, err.Error()))
+			customError.Log()
+			return
+		}
+		requestBodyBytes = requestJSONForm
+	}
+	return
+}
+
+// sendOnce builds one *http.Request from request/requestBodyBytes and sends
+// it via client, without handling the 401/oauth2-refresh retry itself (that
+// lives in doRequest, which calls this once per attempt).
+func (c *Client) sendOnce(ctx context.Context, request *HttpRequestParams, requestBodyBytes []byte, client *http.Client, parsedURL *url.URL, refreshedOAuth2 bool) (response *http.Response, customError error.CustomError) {
+	var requestBuffer io.Reader
+	contentTypeHeader := string(request.contentType)
+	if request.contentType == CONTENT_TYPE_MULTIPART_FORM_DATA {
+		multipartBody, multipartContentType := newMultipartBody(request.multipartFields, request.multipartFiles)
+		contentTypeHeader = multipartContentType
+		requestBuffer = multipartBody
+	} else if requestBodyBytes != nil {
+		requestBuffer = bytes.NewReader(requestBodyBytes)
 	}
 
+	httpRequest, err := http.NewRequest(request.method, parsedURL.String(), requestBuffer)
 	if err != nil {
-		customError = error.NewCustomError(error.API_REQUEST_CREATION_ERROR, fmt.Sprintf("url: %s; error: %s", url.String(), err.Error()))
+		customError = error.NewCustomError(error.API_REQUEST_CREATION_ERROR, fmt.Sprintf("url: %s; error: %s", parsedURL.String(), err.Error()))
 		customError.Log()
 		return
 	}
+	httpRequest = httpRequest.WithContext(ctx)
 
-	client := &http.Client{Timeout: DEFAULT_TIMEOUT}
-	if request.timeout != time.Duration(0) {
-		client.Timeout = request.timeout
+	if request.queryParams != nil {
+		q := httpRequest.URL.Query()
+		for k, v := range request.queryParams {
+			q.Add(k, v)
+		}
+		httpRequest.URL.RawQuery = q.Encode()
 	}
 
-	httpRequest.Header.Set("Content-Type", string(request.contentType))
+	httpRequest.Header.Set("Content-Type", contentTypeHeader)
 
 	if request.authToken != NOT_ASSIGNED {
 		httpRequest.Header.Set("Authorization", fmt.Sprintf("%s %s", AUTHORIZATION_TOKEN_PREFIX, request.authToken))
@@ -205,6 +374,24 @@ func MakeApiCallWithRawResponse(ctx context.Context, request *HttpRequestParams)
 		httpRequest.Header.Set("Authorization", "Basic "+request.basicAuth)
 	}
 
+	if request.oauth1 != nil {
+		authHeader, oauthErr := oauth1AuthHeader(request.oauth1, request.method, httpRequest.URL, request.contentType, requestBodyBytes)
+		if oauthErr.Exists() {
+			customError = oauthErr
+			return
+		}
+		httpRequest.Header.Set("Authorization", authHeader)
+	}
+
+	if request.oauth2Cache != nil {
+		token, oauthErr := request.oauth2Cache.authHeader(ctx, refreshedOAuth2)
+		if oauthErr.Exists() {
+			customError = oauthErr
+			return
+		}
+		httpRequest.Header.Set("Authorization", "Bearer "+token)
+	}
+
 	if request.customHeaders != nil {
 		for k, v := range request.customHeaders {
 			httpRequest.Header.Add(k, v)
@@ -212,28 +399,28 @@ func MakeApiCallWithRawResponse(ctx context.Context, request *HttpRequestParams)
 	}
 
 	response, httpErr := client.Do(httpRequest)
-
 	if httpErr != nil {
-		customError = error.NewCustomError(error.API_REQUEST_ERROR, fmt.Sprintf("url: %s; error: %s", url.String(), httpErr.Error())).
+		customError = error.NewCustomError(error.API_REQUEST_ERROR, fmt.Sprintf("url: %s; error: %s", parsedURL.String(), httpErr.Error())).
 			WithParam("request", fmt.Sprintf("%+v", request))
 		customError.Log()
 		return
 	}
 
+	return
+}
+
+// readApiResponse drains response into body, closing it, and builds the
+// HTTPStatusError a status code outside request's success criteria produces.
+func readApiResponse(response *http.Response, request *HttpRequestParams) (body []byte, statusCode int, headers http.Header, statusErr *HTTPStatusError) {
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
-		body, _ = ioutil.ReadAll(response.Body)
-		responseMap := map[string]string{}
-		err = json.Unmarshal(body, &responseMap)
-		customError = error.NewCustomError(error.API_REQUEST_STATUS_ERROR, fmt.Sprintf("url: %s; status code: %d; status: %s; body: %+v", url.String(), response.StatusCode, response.Status, responseMap)).
-			WithParam("response", string(body)).
-			WithParam("request", fmt.Sprintf("%+v", request))
-		customError.WithParam("response-json", string(body))
-		customError.Log()
-		return
+	statusCode = response.StatusCode
+	headers = response.Header
+	body, _ = ioutil.ReadAll(response.Body)
+
+	if !request.isSuccessStatus(statusCode) {
+		statusErr = &HTTPStatusError{StatusCode: statusCode, Headers: headers, Body: body}
 	}
 
-	body, _ = ioutil.ReadAll(response.Body)
 	return
 }
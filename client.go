@@ -0,0 +1,70 @@
+package http_wrapper
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	DEFAULT_MAX_IDLE_CONNS_PER_HOST = 100
+	DEFAULT_KEEP_ALIVE              = 30 * time.Second
+)
+
+// ClientConfig configures the *http.Client a Client wraps. Zero values fall
+// back to sane defaults tuned for reusing connections across many calls.
+type ClientConfig struct {
+	Transport           http.RoundTripper // custom transport, takes precedence over MaxIdleConnsPerHost, KeepAlive and TLSConfig
+	MaxIdleConnsPerHost int               // default DEFAULT_MAX_IDLE_CONNS_PER_HOST
+	KeepAlive           time.Duration     // default DEFAULT_KEEP_ALIVE
+	TLSConfig           *tls.Config       // optional TLS config for the default transport
+	Timeout             time.Duration     // default DEFAULT_TIMEOUT, overridable per-request via HttpRequestParams.SetTimeout
+	Middlewares         []Middleware      // applied outermost-first via Client.Use, see middleware.go
+}
+
+// Client wraps a reusable *http.Client so callers get connection pooling
+// (keep-alives, idle conns) across calls instead of paying a fresh dial/TLS
+// handshake per request. The package-level MakeApiCall/MakeApiCallWithRetries/
+// MakeApiCallWithRawResponse functions are thin wrappers around defaultClient
+// for callers who don't need a custom transport.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient builds a Client from config, falling back to defaults for any
+// zero-valued fields.
+func NewClient(config ClientConfig) *Client {
+	transport := config.Transport
+	if transport == nil {
+		maxIdleConnsPerHost := config.MaxIdleConnsPerHost
+		if maxIdleConnsPerHost == 0 {
+			maxIdleConnsPerHost = DEFAULT_MAX_IDLE_CONNS_PER_HOST
+		}
+		keepAlive := config.KeepAlive
+		if keepAlive == 0 {
+			keepAlive = DEFAULT_KEEP_ALIVE
+		}
+		transport = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   DEFAULT_TIMEOUT,
+				KeepAlive: keepAlive,
+			}).DialContext,
+			MaxIdleConnsPerHost: maxIdleConnsPerHost,
+			TLSClientConfig:     config.TLSConfig,
+		}
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = DEFAULT_TIMEOUT
+	}
+
+	client := &Client{httpClient: &http.Client{Transport: transport, Timeout: timeout}}
+	client.Use(config.Middlewares...)
+	return client
+}
+
+// defaultClient backs the package-level MakeApiCall family.
+var defaultClient = NewClient(ClientConfig{})
@@ -0,0 +1,176 @@
+package http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Middleware wraps an http.RoundTripper with additional behavior — logging,
+// metrics, tracing, circuit breaking — composed onto a Client's transport via
+// Client.Use or ClientConfig.Middlewares. Middlewares run in the order
+// passed, outermost first: the first middleware sees the request before any
+// of the others and the response after all of them.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// Use wraps the Client's transport with middlewares, outermost first.
+func (c *Client) Use(middlewares ...Middleware) {
+	transport := c.httpClient.Transport
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+	c.httpClient.Transport = transport
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Logger is satisfied by *log.Logger, among others.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// LoggingMiddleware logs one line per request with method, URL, status code
+// (or error) and latency, redacting redactHeaders (case-insensitive) from
+// the logged request headers.
+func LoggingMiddleware(logger Logger, redactHeaders ...string) Middleware {
+	redacted := make(map[string]bool, len(redactHeaders))
+	for _, header := range redactHeaders {
+		redacted[strings.ToLower(header)] = true
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			headers := make(http.Header, len(req.Header))
+			for k, v := range req.Header {
+				if redacted[strings.ToLower(k)] {
+					headers.Set(k, "REDACTED")
+				} else {
+					headers[k] = v
+				}
+			}
+
+			start := time.Now()
+			response, err := next.RoundTrip(req)
+			latency := time.Since(start)
+
+			if err != nil {
+				logger.Printf("http %s %s headers=%v error=%s latency=%s", req.Method, req.URL, headers, err, latency)
+				return response, err
+			}
+			logger.Printf("http %s %s headers=%v status=%d latency=%s", req.Method, req.URL, headers, response.StatusCode, latency)
+			return response, err
+		})
+	}
+}
+
+// MetricsRecorder receives one observation per completed round trip.
+// Implementations typically forward these to a Prometheus counter/histogram
+// pair labeled by method, host and status.
+type MetricsRecorder interface {
+	ObserveRequest(method string, host string, statusCode int, duration time.Duration)
+}
+
+// MetricsMiddleware reports request count and latency to recorder, labeled
+// by method, host and status code (0 if the round trip itself errored).
+func MetricsMiddleware(recorder MetricsRecorder) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			response, err := next.RoundTrip(req)
+
+			statusCode := 0
+			if response != nil {
+				statusCode = response.StatusCode
+			}
+			recorder.ObserveRequest(req.Method, req.URL.Host, statusCode, time.Since(start))
+			return response, err
+		})
+	}
+}
+
+// traceparentContextKey is the context.Context key TracingMiddleware reads a
+// W3C Trace Context "traceparent" value from.
+type traceparentContextKey struct{}
+
+// ContextWithTraceparent returns a context carrying traceparent, which
+// TracingMiddleware propagates onto outgoing requests' "traceparent" header.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceparentContextKey{}, traceparent)
+}
+
+// TracingMiddleware injects the "traceparent" header (W3C Trace Context)
+// carried on the request's context via ContextWithTraceparent onto outgoing
+// requests, so a caller's span propagates to the downstream API.
+func TracingMiddleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if traceparent, ok := req.Context().Value(traceparentContextKey{}).(string); ok && traceparent != "" {
+				req = req.Clone(req.Context())
+				req.Header.Set("traceparent", traceparent)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// CircuitBreakerOpenError is returned by CircuitBreakerMiddleware's
+// RoundTripper when the breaker is open, short-circuiting without calling
+// the wrapped transport.
+type CircuitBreakerOpenError struct{}
+
+func (CircuitBreakerOpenError) Error() string {
+	return "circuit breaker open"
+}
+
+// CircuitBreakerMiddleware trips open after failureThreshold consecutive
+// failures (round-trip errors or 5xx responses), short-circuiting every
+// request with CircuitBreakerOpenError until resetTimeout has elapsed since
+// it tripped, at which point it admits exactly one trial request (denying
+// any concurrent ones with CircuitBreakerOpenError too) to decide whether to
+// close again.
+func CircuitBreakerMiddleware(failureThreshold int, resetTimeout time.Duration) Middleware {
+	var mu sync.Mutex
+	var consecutiveFailures int
+	var openedAt time.Time
+	var probing bool
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			tripped := consecutiveFailures >= failureThreshold
+			isProbe := false
+			if tripped {
+				if time.Since(openedAt) < resetTimeout || probing {
+					mu.Unlock()
+					return nil, CircuitBreakerOpenError{}
+				}
+				isProbe = true
+				probing = true
+			}
+			mu.Unlock()
+
+			response, err := next.RoundTrip(req)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if isProbe {
+				probing = false
+			}
+			if err != nil || (response != nil && response.StatusCode >= 500) {
+				consecutiveFailures++
+				if consecutiveFailures >= failureThreshold {
+					openedAt = time.Now()
+				}
+			} else {
+				consecutiveFailures = 0
+			}
+			return response, err
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package http_wrapper
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+)
+
+// CONTENT_TYPE_MULTIPART_FORM_DATA is set automatically by SetMultipartBody;
+// the actual Content-Type header also carries the generated boundary.
+const CONTENT_TYPE_MULTIPART_FORM_DATA ContentType = "multipart/form-data"
+
+// FileUpload describes one file part of a multipart/form-data body set via
+// SetMultipartBody. ContentType is optional; when empty, the part is written
+// with multipart.Writer.CreateFormFile's default ("application/octet-stream").
+type FileUpload struct {
+	FieldName   string
+	FileName    string
+	Reader      io.Reader
+	ContentType string
+}
+
+// SetMultipartBody configures the request to send fields and files as a
+// multipart/form-data body and sets ContentType to
+// CONTENT_TYPE_MULTIPART_FORM_DATA. Files are streamed directly from their
+// Reader rather than buffered into memory, so each FileUpload.Reader can only
+// be consumed once: MakeApiCallWithRetries never retries a multipart
+// request, and the OAuth2 401 re-send is skipped for one too, rather than
+// resending drained readers as empty file parts.
+func (a *HttpRequestParams) SetMultipartBody(fields map[string]string, files map[string]FileUpload) {
+	a.multipartFields = fields
+	a.multipartFiles = files
+	a.contentType = CONTENT_TYPE_MULTIPART_FORM_DATA
+}
+
+// newMultipartBody streams fields and files into a multipart/form-data body
+// over an io.Pipe so large files aren't buffered into memory. It returns the
+// pipe's read side (the caller must read it to completion or close it to
+// unblock the writing goroutine) and the Content-Type header value, boundary
+// included.
+func newMultipartBody(fields map[string]string, files map[string]FileUpload) (io.ReadCloser, string) {
+	pipeReader, pipeWriter := io.Pipe()
+	writer := multipart.NewWriter(pipeWriter)
+
+	go func() {
+		err := writeMultipartParts(writer, fields, files)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+		pipeWriter.CloseWithError(err)
+	}()
+
+	return pipeReader, writer.FormDataContentType()
+}
+
+func writeMultipartParts(writer *multipart.Writer, fields map[string]string, files map[string]FileUpload) error {
+	for field, value := range fields {
+		if err := writer.WriteField(field, value); err != nil {
+			return err
+		}
+	}
+
+	for field, file := range files {
+		part, err := multipartFilePart(writer, field, file)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, file.Reader); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func multipartFilePart(writer *multipart.Writer, field string, file FileUpload) (io.Writer, error) {
+	if file.ContentType == "" {
+		return writer.CreateFormFile(field, file.FileName)
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, field, file.FileName))
+	header.Set("Content-Type", file.ContentType)
+	return writer.CreatePart(header)
+}
@@ -0,0 +1,111 @@
+package http_wrapper
+
+import (
+	error "github.com/abhinav-codealchemist/custom-error-go"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryableStatusCodes are the response status codes MakeApiCallWithRetries
+// retries against when no custom RetryPolicy.RetryableStatusCodes is supplied.
+var DefaultRetryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// RetryPolicy configures the backoff behavior of MakeApiCallWithRetries.
+type RetryPolicy struct {
+	InitialInterval      time.Duration // delay before the first retry
+	Multiplier           float64       // growth factor applied to the interval after each attempt
+	MaxInterval          time.Duration // upper bound on the computed delay, 0 means unbounded
+	MaxElapsedTime       time.Duration // give up once this much time has elapsed since the first attempt, 0 means unbounded
+	RandomizationFactor  float64       // jitter factor in [0, 1]: the computed delay is scaled by a random value in [1-factor, 1+factor]
+	RetryableStatusCodes map[int]bool  // response status codes worth retrying, defaults to DefaultRetryableStatusCodes
+	OnRetry              func(attempt int, customError error.CustomError, delay time.Duration)
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used by MakeApiCallWithRetries when
+// the request has none set.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		InitialInterval:      500 * time.Millisecond,
+		Multiplier:           2,
+		MaxInterval:          30 * time.Second,
+		MaxElapsedTime:       2 * time.Minute,
+		RandomizationFactor:  0.5,
+		RetryableStatusCodes: DefaultRetryableStatusCodes,
+	}
+}
+
+// nextDelay computes the backoff delay before the given (zero-based) attempt,
+// then picks it uniformly at random within +/- RandomizationFactor of itself.
+func (p *RetryPolicy) nextDelay(attempt int) time.Duration {
+	interval := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(attempt))
+	if p.MaxInterval > 0 && interval > float64(p.MaxInterval) {
+		interval = float64(p.MaxInterval)
+	}
+	if p.RandomizationFactor > 0 {
+		delta := p.RandomizationFactor * interval
+		low := interval - delta
+		high := interval + delta
+		interval = low + rand.Float64()*(high-low)
+	}
+	if interval < 0 {
+		interval = 0
+	}
+	return time.Duration(interval)
+}
+
+// isRetryableStatus reports whether statusCode should trigger a retry under p.
+func (p *RetryPolicy) isRetryableStatus(statusCode int) bool {
+	if statusCode == 0 {
+		return false
+	}
+	codes := p.RetryableStatusCodes
+	if codes == nil {
+		codes = DefaultRetryableStatusCodes
+	}
+	return codes[statusCode]
+}
+
+// isRetryableError reports whether customError represents a transient failure
+// worth retrying, as opposed to a request-construction error that will never
+// succeed on replay.
+func isRetryableError(customError error.CustomError) bool {
+	switch customError.ErrorCode() {
+	case error.JSON_SERIALIZATION_ERROR, error.JSON_DESERIALIZATION_ERROR, error.FORM_SERIALIZATION_ERROR, error.API_URL_PARSING_ERROR, error.API_REQUEST_CREATION_ERROR:
+		return false
+	case error.API_REQUEST_ERROR, error.API_REQUEST_STATUS_ERROR:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfterDelay parses the Retry-After header (either delta-seconds or an
+// HTTP-date) and reports the delay it specifies, if any.
+func retryAfterDelay(headers http.Header) (time.Duration, bool) {
+	value := headers.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			seconds = 0
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		delay := time.Until(when)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
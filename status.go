@@ -0,0 +1,53 @@
+package http_wrapper
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HTTPStatusError is returned by MakeApiCallWithRawResponse/MakeApiCall/
+// MakeApiCallWithRetries when the response status code falls outside the
+// request's success criteria (SetSuccessStatusRange, default 200-299, or
+// SetExpectedStatusCodes). It carries the full raw body so callers can
+// inspect rate-limit headers, validation error payloads, or redirect targets
+// without string-parsing a forced map[string]string.
+type HTTPStatusError struct {
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+}
+
+// SetExpectedStatusCodes restricts success to exactly this set of status
+// codes, overriding SetSuccessStatusRange.
+func (a *HttpRequestParams) SetExpectedStatusCodes(codes []int) {
+	expected := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		expected[code] = true
+	}
+	a.expectedStatusCodes = expected
+}
+
+// SetSuccessStatusRange treats any status code in [min, max] as success.
+// Ignored once SetExpectedStatusCodes has been called. Default is 200-299.
+func (a *HttpRequestParams) SetSuccessStatusRange(min, max int) {
+	a.successMin = min
+	a.successMax = max
+}
+
+// isSuccessStatus reports whether statusCode satisfies the request's success
+// criteria: the SetExpectedStatusCodes allow-list if set, else the
+// SetSuccessStatusRange range, else the 200-299 default.
+func (a *HttpRequestParams) isSuccessStatus(statusCode int) bool {
+	if a.expectedStatusCodes != nil {
+		return a.expectedStatusCodes[statusCode]
+	}
+	min, max := a.successMin, a.successMax
+	if min == 0 && max == 0 {
+		min, max = 200, 299
+	}
+	return statusCode >= min && statusCode <= max
+}
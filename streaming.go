@@ -0,0 +1,128 @@
+package http_wrapper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	error "github.com/abhinav-codealchemist/custom-error-go"
+	"io"
+	"net/http"
+)
+
+// MakeApiCallWithReader is a thin wrapper around defaultClient.MakeApiCallWithReader.
+func MakeApiCallWithReader(ctx context.Context, request *HttpRequestParams) (body io.ReadCloser, headers http.Header, statusCode int, customError error.CustomError) {
+	return defaultClient.MakeApiCallWithReader(ctx, request)
+}
+
+// MakeApiCallWithReader performs the request and returns the live response
+// body instead of buffering it, for large downloads or server-sent-event
+// streams that ioutil.ReadAll would OOM on. The caller must close body. It
+// does not evaluate request's success criteria (SetSuccessStatusRange/
+// SetExpectedStatusCodes) — statusCode is returned as-is for the caller to
+// interpret.
+func (c *Client) MakeApiCallWithReader(ctx context.Context, request *HttpRequestParams) (body io.ReadCloser, headers http.Header, statusCode int, customError error.CustomError) {
+	response, customError := c.doRequest(ctx, request)
+	if customError.Exists() {
+		return
+	}
+	return response.Body, response.Header, response.StatusCode, customError
+}
+
+// MakeApiCallStreamJSON is a thin wrapper around defaultClient.MakeApiCallStreamJSON.
+func MakeApiCallStreamJSON(ctx context.Context, request *HttpRequestParams, handler func(json.RawMessage) error) (customError error.CustomError) {
+	return defaultClient.MakeApiCallStreamJSON(ctx, request, handler)
+}
+
+// MakeApiCallStreamJSON performs the request and decodes the response body
+// element-by-element via MakeApiCallWithReader, calling handler with each
+// element's raw JSON. It transparently handles either a top-level JSON array
+// or newline-delimited JSON (NDJSON), whichever the body turns out to be. It
+// never buffers the full body, so it's safe for paginated bulk exports or
+// log streams. Decoding stops at handler's first error, which is wrapped
+// into the returned customError.
+func (c *Client) MakeApiCallStreamJSON(ctx context.Context, request *HttpRequestParams, handler func(json.RawMessage) error) (customError error.CustomError) {
+	body, _, _, customError := c.MakeApiCallWithReader(ctx, request)
+	if customError.Exists() {
+		return
+	}
+	defer body.Close()
+
+	reader := bufio.NewReader(body)
+	isArray, customError := peekIsJSONArray(reader, request)
+	if customError.Exists() {
+		return
+	}
+
+	decoder := json.NewDecoder(reader)
+
+	if isArray {
+		if _, err := decoder.Token(); err != nil {
+			customError = error.NewCustomError(error.JSON_DESERIALIZATION_ERROR, err.Error()).
+				WithParam("request", fmt.Sprintf("%+v", request))
+			customError.Log()
+			return
+		}
+		for decoder.More() {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				customError = error.NewCustomError(error.JSON_DESERIALIZATION_ERROR, err.Error()).
+					WithParam("request", fmt.Sprintf("%+v", request))
+				customError.Log()
+				return
+			}
+			if err := handler(raw); err != nil {
+				customError = error.NewCustomError(error.JSON_DESERIALIZATION_ERROR, fmt.Sprintf("stream handler error: %s", err.Error())).
+					WithParam("request", fmt.Sprintf("%+v", request))
+				customError.Log()
+				return
+			}
+		}
+		return
+	}
+
+	for {
+		var raw json.RawMessage
+		decodeErr := decoder.Decode(&raw)
+		if decodeErr == io.EOF {
+			return
+		}
+		if decodeErr != nil {
+			customError = error.NewCustomError(error.JSON_DESERIALIZATION_ERROR, decodeErr.Error()).
+				WithParam("request", fmt.Sprintf("%+v", request))
+			customError.Log()
+			return
+		}
+		if err := handler(raw); err != nil {
+			customError = error.NewCustomError(error.JSON_DESERIALIZATION_ERROR, fmt.Sprintf("stream handler error: %s", err.Error())).
+				WithParam("request", fmt.Sprintf("%+v", request))
+			customError.Log()
+			return
+		}
+	}
+}
+
+// peekIsJSONArray reports whether reader's first non-whitespace byte opens a
+// JSON array, without consuming anything handler or json.Decoder still need.
+func peekIsJSONArray(reader *bufio.Reader, request *HttpRequestParams) (isArray bool, customError error.CustomError) {
+	for {
+		next, err := reader.Peek(1)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			customError = error.NewCustomError(error.JSON_DESERIALIZATION_ERROR, err.Error()).
+				WithParam("request", fmt.Sprintf("%+v", request))
+			customError.Log()
+			return
+		}
+
+		switch next[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.Discard(1)
+			continue
+		}
+		isArray = next[0] == '['
+		return
+	}
+}